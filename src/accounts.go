@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// accountsConfigKey is the sink key under which the account registry is
+// persisted, so it survives a restart without needing a redeploy.
+const accountsConfigKey = "_config/accounts.json"
+
+// AccountRegistry is an in-memory, mutex-protected set of account aliases
+// that can be mutated at runtime via the admin API instead of requiring a
+// redeploy to change ACCOUNT_ALIASES.
+type AccountRegistry struct {
+	mu      sync.RWMutex
+	aliases map[string]struct{}
+	sink    Sink
+}
+
+// newAccountRegistry seeds the registry from ACCOUNT_ALIASES, then merges in
+// whatever was last persisted to the config sink (e.g. accounts added via
+// the admin API since the last deploy), so a restart doesn't drop them. The
+// result is the union of both lists: an alias removed via the admin API but
+// still listed in ACCOUNT_ALIASES is registered again after a restart.
+func newAccountRegistry(ctx context.Context, sink Sink) *AccountRegistry {
+	r := &AccountRegistry{aliases: make(map[string]struct{}), sink: sink}
+	for _, a := range getAccountAliases() {
+		r.aliases[a] = struct{}{}
+	}
+
+	persisted, err := loadPersistedAliases(ctx, sink)
+	if err != nil {
+		log.Printf("Warning: failed to load persisted account registry, starting from ACCOUNT_ALIASES only: %v", err)
+	}
+	for _, a := range persisted {
+		r.aliases[a] = struct{}{}
+	}
+
+	return r
+}
+
+// loadPersistedAliases reads the alias list last written by persist, if any.
+func loadPersistedAliases(ctx context.Context, sink Sink) ([]string, error) {
+	data, err := sink.Read(ctx, accountsConfigKey)
+	if err != nil {
+		if err == ErrSinkKeyNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read persisted account registry: %w", err)
+	}
+	var aliases []string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse persisted account registry: %w", err)
+	}
+	return aliases, nil
+}
+
+// List returns the registered aliases in sorted order.
+func (r *AccountRegistry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]string, 0, len(r.aliases))
+	for a := range r.aliases {
+		result = append(result, a)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Add registers alias, persisting the updated registry to the config sink.
+func (r *AccountRegistry) Add(ctx context.Context, alias string) error {
+	r.mu.Lock()
+	r.aliases[alias] = struct{}{}
+	r.mu.Unlock()
+	return r.persist(ctx)
+}
+
+// Remove unregisters alias, persisting the updated registry to the config sink.
+func (r *AccountRegistry) Remove(ctx context.Context, alias string) error {
+	r.mu.Lock()
+	delete(r.aliases, alias)
+	r.mu.Unlock()
+	return r.persist(ctx)
+}
+
+// persist writes the current alias list to the config sink as JSON.
+func (r *AccountRegistry) persist(ctx context.Context) error {
+	data, err := json.Marshal(r.List())
+	if err != nil {
+		return fmt.Errorf("failed to marshal account registry: %w", err)
+	}
+	if _, err := r.sink.Write(ctx, accountsConfigKey, data); err != nil {
+		return fmt.Errorf("failed to persist account registry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// memSink is a minimal in-memory Sink for tests that don't need a real backend.
+type memSink struct {
+	data map[string][]byte
+}
+
+func newMemSink() *memSink {
+	return &memSink{data: make(map[string][]byte)}
+}
+
+func (s *memSink) Write(ctx context.Context, key string, data []byte) (string, error) {
+	s.data[key] = append([]byte(nil), data...)
+	return "ok", nil
+}
+
+func (s *memSink) Read(ctx context.Context, key string) ([]byte, error) {
+	data, ok := s.data[key]
+	if !ok {
+		return nil, ErrSinkKeyNotFound
+	}
+	return data, nil
+}
+
+func TestAccountRegistryAddRemoveList(t *testing.T) {
+	ctx := context.Background()
+	r := &AccountRegistry{aliases: make(map[string]struct{}), sink: newMemSink()}
+
+	if got := r.List(); len(got) != 0 {
+		t.Fatalf("List() = %v, want empty", got)
+	}
+
+	if err := r.Add(ctx, "invoices"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := r.Add(ctx, "payroll"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if got, want := r.List(), []string{"invoices", "payroll"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+
+	if err := r.Remove(ctx, "invoices"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got, want := r.List(), []string{"payroll"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestNewAccountRegistryMergesPersistedAliases(t *testing.T) {
+	sink := newMemSink()
+	sink.data[accountsConfigKey] = []byte(`["persisted-account"]`)
+
+	r := newAccountRegistry(context.Background(), sink)
+
+	got := r.List()
+	want := []string{"invoices", "persisted-account"} // "invoices" is the ACCOUNT_ALIASES default
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestNewAccountRegistryToleratesMissingPersistedConfig(t *testing.T) {
+	r := newAccountRegistry(context.Background(), newMemSink())
+	if got, want := r.List(), []string{"invoices"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadPersistedAliasesReturnsNilOnNotFound(t *testing.T) {
+	aliases, err := loadPersistedAliases(context.Background(), newMemSink())
+	if err != nil {
+		t.Fatalf("loadPersistedAliases() error = %v", err)
+	}
+	if aliases != nil {
+		t.Fatalf("loadPersistedAliases() = %v, want nil", aliases)
+	}
+}
+
+func TestLoadPersistedAliasesPropagatesParseErrors(t *testing.T) {
+	s := &memSink{data: make(map[string][]byte)}
+	s.data[accountsConfigKey] = []byte("not-json")
+
+	if _, err := loadPersistedAliases(context.Background(), s); err == nil {
+		t.Fatal("expected an error for malformed persisted config")
+	} else if errors.Is(err, ErrSinkKeyNotFound) {
+		t.Fatalf("expected a parse error, got ErrSinkKeyNotFound: %v", err)
+	}
+}
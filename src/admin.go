@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// adminAddr is the listener address for the admin control plane, kept
+// separate from the main :8080 listener so it isn't exposed alongside /health.
+const adminAddr = ":8081"
+
+// adminTokenSecretName is the Key Vault secret holding the shared-secret
+// bearer token required on every admin request.
+const adminTokenSecretName = "admin-api-token"
+
+// validAliasPattern restricts account aliases to a safe charset, since an
+// alias flows unsanitized into sink keys (and, for SINK=file, a filesystem
+// path) — this rejects "/", "..", and anything else that could escape the
+// intended key prefix.
+var validAliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// AdminServer exposes runtime account management endpoints, protected by a
+// shared-secret bearer token loaded from Key Vault.
+type AdminServer struct {
+	registry *AccountRegistry
+	sink     Sink
+	token    string
+}
+
+// startAdminServer authenticates, fetches the admin bearer token from Key
+// Vault, and starts the admin HTTP API on adminAddr in the background.
+func startAdminServer(ctx context.Context, registry *AccountRegistry, sink Sink) {
+	cred, err := azAuth()
+	if err != nil {
+		log.Printf("Admin server disabled: authentication failed: %v", err)
+		return
+	}
+
+	token, err := retrieveKvSecret(ctx, adminTokenSecretName, cred)
+	if err != nil {
+		log.Printf("Admin server disabled: failed to retrieve %q: %v", adminTokenSecretName, err)
+		return
+	}
+
+	srv := &AdminServer{registry: registry, sink: sink, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/accounts", srv.withAuth(srv.handleAccounts))
+	mux.HandleFunc("/admin/accounts/", srv.withAuth(srv.handleAccountByAlias))
+	mux.HandleFunc("/admin/cache/flush", srv.withAuth(srv.handleCacheFlush))
+
+	log.Printf("Admin server is starting on %s", adminAddr)
+	go func() {
+		if err := http.ListenAndServe(adminAddr, mux); err != nil {
+			log.Printf("Admin server stopped: %v", err)
+		}
+	}()
+}
+
+// withAuth rejects requests that don't present the configured bearer token.
+func (s *AdminServer) withAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		authHeader := r.Header.Get("Authorization")
+		presented, ok := strings.CutPrefix(authHeader, prefix)
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(s.token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleAccounts handles GET/POST on /admin/accounts.
+func (s *AdminServer) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"accounts": s.registry.List()})
+	case http.MethodPost:
+		var body struct {
+			Alias string `json:"alias"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Alias == "" {
+			http.Error(w, "expected JSON body with non-empty \"alias\"", http.StatusBadRequest)
+			return
+		}
+		if !validAliasPattern.MatchString(body.Alias) {
+			http.Error(w, "alias must match "+validAliasPattern.String(), http.StatusBadRequest)
+			return
+		}
+		if err := s.registry.Add(r.Context(), body.Alias); err != nil {
+			http.Error(w, fmt.Sprintf("failed to add account: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"accounts": s.registry.List()})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAccountByAlias handles DELETE /admin/accounts/{alias} and
+// POST /admin/accounts/{alias}/fetch.
+func (s *AdminServer) handleAccountByAlias(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/admin/accounts/")
+	alias, action, hasAction := strings.Cut(path, "/")
+
+	if alias == "" {
+		http.Error(w, "missing account alias", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodDelete && !hasAction:
+		if err := s.registry.Remove(r.Context(), alias); err != nil {
+			http.Error(w, fmt.Sprintf("failed to remove account: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"accounts": s.registry.List()})
+	case r.Method == http.MethodPost && action == "fetch":
+		startDate, endDate := getDatesFromQuery(r)
+		cred, err := azAuth()
+		if err != nil {
+			http.Error(w, "authentication failed", http.StatusInternalServerError)
+			return
+		}
+		result := processAccount(r.Context(), cred, s.sink, alias, startDate, endDate)
+		status := http.StatusOK
+		if result.Status != "success" {
+			status = http.StatusInternalServerError
+		}
+		writeJSON(w, status, result)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCacheFlush discards all cached secret values, forcing the next
+// lookup for each to hit Key Vault. Intended to be called right after a
+// token rotation.
+func (s *AdminServer) handleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secretCache.Flush()
+	writeJSON(w, http.StatusOK, map[string]any{"flushed": true})
+}
+
+// writeJSON marshals v as JSON with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode admin response: %v", err)
+	}
+}
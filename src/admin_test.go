@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestValidAliasPattern(t *testing.T) {
+	valid := []string{"invoices", "Payroll_2024", "account-1", "a"}
+	invalid := []string{"", "../etc/passwd", "has/slash", "has space", "semi;colon", "..", "."}
+
+	for _, alias := range valid {
+		if !validAliasPattern.MatchString(alias) {
+			t.Errorf("validAliasPattern.MatchString(%q) = false, want true", alias)
+		}
+	}
+	for _, alias := range invalid {
+		if validAliasPattern.MatchString(alias) {
+			t.Errorf("validAliasPattern.MatchString(%q) = true, want false", alias)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// secretCacheTTL controls how long a secret value retrieved from Key Vault
+// is reused before being re-fetched. Tokens rotate rarely, so this trades a
+// bounded staleness window for materially less Key Vault traffic.
+var secretCacheTTL = getEnvDuration("SECRET_CACHE_TTL", 10*time.Minute)
+
+// secretCacheEntry holds a cached secret value and when it expires.
+type secretCacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// SecretCache is a simple TTL cache for Key Vault secret values, keyed by
+// secret name.
+type SecretCache struct {
+	mu      sync.RWMutex
+	entries map[string]secretCacheEntry
+}
+
+// newSecretCache builds an empty SecretCache.
+func newSecretCache() *SecretCache {
+	return &SecretCache{entries: make(map[string]secretCacheEntry)}
+}
+
+// Get returns the cached value for key, if present and not expired.
+func (c *SecretCache) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key with the configured TTL.
+func (c *SecretCache) Set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = secretCacheEntry{value: value, expiresAt: time.Now().Add(secretCacheTTL)}
+}
+
+// Flush discards all cached values, forcing the next lookup to hit Key Vault.
+func (c *SecretCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]secretCacheEntry)
+}
+
+// secretCache is the package-wide secret value cache.
+var secretCache = newSecretCache()
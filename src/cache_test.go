@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecretCacheGetSetFlush(t *testing.T) {
+	c := newSecretCache()
+
+	if _, ok := c.Get("fio-token-invoices"); ok {
+		t.Fatal("expected cache miss before Set")
+	}
+
+	c.Set("fio-token-invoices", "secret-value")
+	value, ok := c.Get("fio-token-invoices")
+	if !ok || value != "secret-value" {
+		t.Fatalf("Get() = %q, %v, want %q, true", value, ok, "secret-value")
+	}
+
+	c.Flush()
+	if _, ok := c.Get("fio-token-invoices"); ok {
+		t.Fatal("expected cache miss after Flush")
+	}
+}
+
+func TestSecretCacheExpiry(t *testing.T) {
+	c := newSecretCache()
+
+	c.mu.Lock()
+	c.entries["fio-token-invoices"] = secretCacheEntry{
+		value:     "stale",
+		expiresAt: time.Now().Add(-time.Second),
+	}
+	c.mu.Unlock()
+
+	if _, ok := c.Get("fio-token-invoices"); ok {
+		t.Fatal("expected expired entry to be treated as a miss")
+	}
+}
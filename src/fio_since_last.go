@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// pullMode selects how processAccount determines the window of transactions
+// to pull for an account.
+type pullMode string
+
+const (
+	modePeriod    pullMode = "period"     // fixed startDate/endDate window (original behavior)
+	modeSinceLast pullMode = "since-last" // Fio cursor-based incremental pull
+)
+
+var (
+	pullModeSetting     = pullMode(getEnvOrDefault("MODE", string(modePeriod)))
+	rateLimitMaxRetries = getEnvInt("RATE_LIMIT_MAX_RETRIES", 5)
+	rateLimitBaseDelay  = getEnvPositiveDuration("RATE_LIMIT_BASE_DELAY", time.Second)
+)
+
+// fioLastResponse captures the subset of Fio's transaction export we need to
+// advance the cursor; the rest of the document is passed through untouched
+// as the stored payload.
+type fioLastResponse struct {
+	AccountStatement struct {
+		Info struct {
+			IDLastDownload string `json:"idLastDownload"`
+		} `json:"info"`
+	} `json:"accountStatement"`
+}
+
+// fetchSinceLastAndAdvance fetches transactions since the last-seen cursor
+// for account and advances Fio's server-side cursor (via set-last-id) so the
+// next call doesn't redeliver the same transactions. Fio is the sole source
+// of truth for the cursor; nothing is cached locally.
+func fetchSinceLastAndAdvance(ctx context.Context, account, token string) ([]byte, error) {
+	data, err := FetchSinceLast(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch since-last transactions: %w", err)
+	}
+
+	var parsed fioLastResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse since-last response: %w", err)
+	}
+	lastID := parsed.AccountStatement.Info.IDLastDownload
+	if lastID == "" {
+		// Nothing new since the last pull; Fio still echoes the current cursor.
+		return data, nil
+	}
+
+	if err := SetLastID(ctx, token, lastID); err != nil {
+		return nil, fmt.Errorf("failed to advance Fio cursor: %w", err)
+	}
+
+	return data, nil
+}
+
+// FetchSinceLast calls Fio's /rest/last/{token}/transactions.{fmt} endpoint,
+// which returns only transactions since the previously acknowledged cursor.
+func FetchSinceLast(ctx context.Context, token string) ([]byte, error) {
+	fullURL := fmt.Sprintf("https://fioapi.fio.cz/v1/rest/last/%s/transactions.%s", token, format)
+	resp, err := doWithRateLimitRetry(ctx, http.MethodGet, fullURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-200 status: %d, Response: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// SetLastID calls Fio's /rest/set-last-id/{token}/{id}/ endpoint to advance
+// the server-side cursor so the next /last/ call doesn't redeliver id.
+func SetLastID(ctx context.Context, token, id string) error {
+	fullURL := fmt.Sprintf("https://fioapi.fio.cz/v1/rest/set-last-id/%s/%s/", token, id)
+	resp, err := doWithRateLimitRetry(ctx, http.MethodGet, fullURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("set-last-id returned non-200 status: %d, Response: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// doWithRateLimitRetry issues a request, retrying with exponential backoff
+// and jitter when Fio responds 409 (its per-token 30-second rate limit).
+func doWithRateLimitRetry(ctx context.Context, method, url string) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= rateLimitMaxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusConflict {
+			return resp, nil
+		}
+		resp.Body.Close()
+		lastErr = fmt.Errorf("rate limited (409) after %d attempts", attempt+1)
+
+		if attempt == rateLimitMaxRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoffWithJitter(rateLimitBaseDelay, attempt)):
+		}
+	}
+	return nil, lastErr
+}
+
+// backoffWithJitter returns the exponential backoff delay for attempt,
+// plus up to 50% jitter, so a burst of accounts retrying together doesn't
+// all retry in lockstep. Guards baseDelay <= 0 (e.g. a misconfigured
+// RATE_LIMIT_BASE_DELAY) so rand.Int63n never sees a non-positive argument.
+func backoffWithJitter(baseDelay time.Duration, attempt int) time.Duration {
+	delay := baseDelay * time.Duration(1<<attempt)
+	var jitter time.Duration
+	if half := int64(delay) / 2; half > 0 {
+		jitter = time.Duration(rand.Int63n(half))
+	}
+	return delay + jitter
+}
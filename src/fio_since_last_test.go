@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffWithJitterBounds(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 6; attempt++ {
+		want := base * time.Duration(1<<attempt)
+		for i := 0; i < 20; i++ {
+			got := backoffWithJitter(base, attempt)
+			if got < want || got >= want+want/2 {
+				t.Fatalf("attempt %d: backoffWithJitter() = %v, want in [%v, %v)", attempt, got, want, want+want/2)
+			}
+		}
+	}
+}
+
+func TestBackoffWithJitterDoesNotPanicOnNonPositiveDelay(t *testing.T) {
+	for _, base := range []time.Duration{0, -time.Second, 1} {
+		for attempt := 0; attempt < 3; attempt++ {
+			if got := backoffWithJitter(base, attempt); got < base*time.Duration(1<<attempt) {
+				t.Fatalf("backoffWithJitter(%v, %d) = %v, want >= %v", base, attempt, got, base*time.Duration(1<<attempt))
+			}
+		}
+	}
+}
@@ -2,22 +2,27 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 const (
 	format             = "json"
 	defaultHTTPTimeout = 90 * time.Second
+	defaultConcurrency = 4
 )
 
 var (
@@ -27,8 +32,32 @@ var (
 	storageContainerName = getEnvOrDefault("STORAGE_CONTAINER_NAME", "raw")
 	accountAliases       = getEnvOrDefault("ACCOUNT_ALIASES", "invoices")
 	httpClient           = newHTTPClient(getEnvDuration("HTTP_CLIENT_TIMEOUT", defaultHTTPTimeout))
+	maxConcurrency       = getEnvInt("MAX_CONCURRENCY", defaultConcurrency)
 )
 
+var (
+	kvClientOnce sync.Once
+	kvClient     *azsecrets.Client
+	kvClientErr  error
+)
+
+// getKvClient returns the package-wide Key Vault client, constructing it
+// once on first use instead of per-request so repeated secret lookups don't
+// pay redundant auth/connection overhead.
+func getKvClient(cred *azidentity.DefaultAzureCredential) (*azsecrets.Client, error) {
+	kvClientOnce.Do(func() {
+		kvClient, kvClientErr = azsecrets.NewClient(keyVaultURL, cred, nil)
+		if kvClientErr != nil {
+			log.Printf("Failed to create KeyVault client: %v\n", kvClientErr)
+		}
+	})
+	return kvClient, kvClientErr
+}
+
+// defaultSink is the package-wide Sink instance, constructed once at startup
+// from the SINK env var rather than per-request.
+var defaultSink Sink
+
 // getEnvOrDefault retrieves an environment variable or returns a default value.
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -50,6 +79,30 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvPositiveDuration is like getEnvDuration but additionally rejects
+// zero/negative durations, for settings (e.g. retry base delays) where those
+// would otherwise produce degenerate or invalid behavior downstream.
+func getEnvPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Warning: %s must be a positive duration, got %q, using default: %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvInt retrieves an environment variable as an int or returns a default value.
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Warning: %s has invalid int %q, using default: %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 // getAccountAliases parses comma-separated account aliases from env var
 func getAccountAliases() []string {
 	aliases := strings.Split(accountAliases, ",")
@@ -66,19 +119,51 @@ func getAccountAliases() []string {
 	return result
 }
 
-// AccountResult holds the result of processing a single account
+// AccountResult holds the result of processing a single account.
 type AccountResult struct {
-	Account string
-	Success bool
-	Message string
-	Error   error
+	Account    string `json:"account"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	DurationMs int64  `json:"durationMs"`
 }
 
+// Response is the top-level JSON body returned by handler.
+type Response struct {
+	Processed  int             `json:"processed"`
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	DurationMs int64           `json:"durationMs"`
+	Results    []AccountResult `json:"results"`
+}
+
+// accountRegistry is the runtime-mutable set of accounts the admin API
+// manages. It is seeded from ACCOUNT_ALIASES at startup.
+var accountRegistry *AccountRegistry
+
 func main() {
 	// Configure the logger for local development.
 	// In production you may want to redirect logs to a file or disable debug logs.
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	shutdownTrace, err := initTracer(context.Background())
+	if err != nil {
+		log.Printf("Warning: tracing disabled: %v", err)
+	}
+	defer shutdownTracer(context.Background(), shutdownTrace)
+
+	startupSink, err := newSink(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to initialize sink: %v", err)
+	}
+	defaultSink = startupSink
+	accountRegistry = newAccountRegistry(context.Background(), startupSink)
+	startAdminServer(context.Background(), accountRegistry, startupSink)
+
 	http.HandleFunc("/health", healthHandler)
+	http.HandleFunc("/metrics", metricsHandler.ServeHTTP)
 	http.HandleFunc("/", handler)
 	log.Println("Server is starting on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -91,9 +176,21 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // handler fetches transaction data from FIO API for all configured accounts
-// and writes each to Azure Blob Storage. Continues processing even if one account fails.
+// and writes each to the configured Sink. Accounts are processed concurrently
+// with a bounded worker pool (size MAX_CONCURRENCY, default 4), and a single
+// deadline derived from defaultHTTPTimeout governs the whole batch so one
+// slow account can't stall the others indefinitely. Continues processing
+// even if one account fails.
 func handler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received request from %s", r.RemoteAddr)
+	requestsTotal.Inc()
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(r.Context(), defaultHTTPTimeout)
+	defer cancel()
+
+	ctx, span := startSpan(ctx, "handler")
+	defer span.End()
 
 	cred, err := azAuth()
 	if err != nil {
@@ -102,109 +199,158 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sink := defaultSink
+
 	startDate, endDate := getDatesFromQuery(r)
 	log.Printf("Fetching transactions from %s to %s", startDate, endDate)
 
-	accounts := getAccountAliases()
-	log.Printf("Processing %d accounts: %v", len(accounts), accounts)
+	accounts := accountRegistry.List()
+	log.Printf("Processing %d accounts with concurrency %d: %v", len(accounts), maxConcurrency, accounts)
 
-	results := make([]AccountResult, 0, len(accounts))
-	successCount := 0
+	results := processAccountsConcurrently(ctx, cred, sink, accounts, startDate, endDate)
 
-	for _, account := range accounts {
-		result := processAccount(cred, account, startDate, endDate)
-		results = append(results, result)
-		if result.Success {
+	successCount := 0
+	for _, r := range results {
+		if r.Status == "success" {
 			successCount++
 		}
 	}
 
-	// Build response
-	w.Header().Set("Content-Type", "application/json")
+	resp := Response{
+		Processed:  len(accounts),
+		Succeeded:  successCount,
+		Failed:     len(accounts) - successCount,
+		DurationMs: time.Since(start).Milliseconds(),
+		Results:    results,
+	}
 
-	if successCount == 0 {
-		// All accounts failed
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case len(accounts) == 0:
+		// Nothing to process (e.g. all accounts removed via the admin API)
+		// isn't a failure in itself.
+		w.WriteHeader(http.StatusOK)
+	case successCount == 0:
 		w.WriteHeader(http.StatusInternalServerError)
-	} else if successCount < len(accounts) {
-		// Partial success
+	case successCount < len(accounts):
 		w.WriteHeader(http.StatusPartialContent) // 206
-	} else {
-		// All succeeded
+	default:
 		w.WriteHeader(http.StatusOK)
 	}
 
-	// Write results summary
-	fmt.Fprintf(w, "{\n")
-	fmt.Fprintf(w, "  \"processed\": %d,\n", len(accounts))
-	fmt.Fprintf(w, "  \"succeeded\": %d,\n", successCount)
-	fmt.Fprintf(w, "  \"failed\": %d,\n", len(accounts)-successCount)
-	fmt.Fprintf(w, "  \"results\": [\n")
-	for i, r := range results {
-		comma := ","
-		if i == len(results)-1 {
-			comma = ""
-		}
-		status := "success"
-		errMsg := ""
-		if !r.Success {
-			status = "failed"
-			if r.Error != nil {
-				errMsg = r.Error.Error()
-			}
-		}
-		fmt.Fprintf(w, "    {\"account\": \"%s\", \"status\": \"%s\", \"message\": \"%s\", \"error\": \"%s\"}%s\n",
-			r.Account, status, r.Message, errMsg, comma)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Failed to encode response: %v", err)
 	}
-	fmt.Fprintf(w, "  ]\n")
-	fmt.Fprintf(w, "}\n")
 }
 
-// processAccount handles fetching and storing data for a single account
-func processAccount(cred *azidentity.DefaultAzureCredential, account, startDate, endDate string) AccountResult {
+// processAccountsConcurrently runs processAccount for each account through a
+// bounded worker pool sized by maxConcurrency, preserving the input order in
+// the returned results.
+func processAccountsConcurrently(ctx context.Context, cred *azidentity.DefaultAzureCredential, sink Sink, accounts []string, startDate, endDate string) []AccountResult {
+	return runBounded(maxConcurrency, accounts, func(i int, account string) AccountResult {
+		return processAccount(ctx, cred, sink, account, startDate, endDate)
+	})
+}
+
+// runBounded runs fn for each item through a worker pool capped at limit
+// concurrent goroutines, returning results in the same order as items
+// regardless of completion order.
+func runBounded[T any, R any](limit int, items []T, fn func(int, T) R) []R {
+	results := make([]R, len(items))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item T) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = fn(i, item)
+		}(i, item)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// processAccount handles fetching and storing data for a single account.
+func processAccount(ctx context.Context, cred *azidentity.DefaultAzureCredential, sink Sink, account, startDate, endDate string) AccountResult {
 	log.Printf("[%s] Starting processing", account)
+	start := time.Now()
+
+	ctx, span := startSpan(ctx, "processAccount")
+	span.SetAttributes(attribute.String("fio.account", account))
+	defer span.End()
+
+	result := AccountResult{
+		Account:   account,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
 
 	// Token secret name follows pattern: fio-token-{account}
 	secretName := fmt.Sprintf("fio-token-%s", account)
 
-	token, err := retrieveKvSecret(secretName, cred)
+	token, err := retrieveKvSecret(ctx, secretName, cred)
 	if err != nil {
 		log.Printf("[%s] Failed to retrieve token from secret '%s': %v", account, secretName, err)
-		return AccountResult{
-			Account: account,
-			Success: false,
-			Error:   fmt.Errorf("failed to retrieve token: %w", err),
-		}
+		span.SetStatus(codes.Error, err.Error())
+		result.Status = "failed"
+		result.Error = fmt.Errorf("failed to retrieve token: %w", err).Error()
+		result.DurationMs = time.Since(start).Milliseconds()
+		observeAccountOutcome(account, result.Status, time.Since(start))
+		return result
 	}
 
-	data, err := FetchTransactionData(token, startDate, endDate, format)
+	var data []byte
+	if pullModeSetting == modeSinceLast {
+		data, err = fetchSinceLastAndAdvance(ctx, account, token)
+	} else {
+		data, err = FetchTransactionData(ctx, token, startDate, endDate, format)
+	}
 	if err != nil {
 		log.Printf("[%s] Error fetching data: %v", account, err)
-		return AccountResult{
-			Account: account,
-			Success: false,
-			Error:   fmt.Errorf("failed to fetch data: %w", err),
-		}
+		span.SetStatus(codes.Error, err.Error())
+		result.Status = "failed"
+		result.Error = fmt.Errorf("failed to fetch data: %w", err).Error()
+		result.DurationMs = time.Since(start).Milliseconds()
+		observeAccountOutcome(account, result.Status, time.Since(start))
+		return result
 	}
 
-	// Blob name includes account prefix: {account}/transactions_{start}_{end}.json
-	blobName := fmt.Sprintf("%s/transactions_%s_%s.json", account, startDate, endDate)
+	// since-last mode is polled repeatedly intraday, so it gets its own
+	// timestamped key; reusing the period-mode date key would let each poll
+	// overwrite the previous one and lose earlier same-day transactions.
+	var key string
+	if pullModeSetting == modeSinceLast {
+		key = fmt.Sprintf("%s/since-last_%s.json", account, time.Now().UTC().Format("20060102T150405.000000000Z"))
+	} else {
+		key = fmt.Sprintf("%s/transactions_%s_%s.json", account, startDate, endDate)
+	}
 
-	result, err := writeBlob(*cred, storageContainerName, blobName, data)
+	sinkStart := time.Now()
+	ctx, writeSpan := startSpan(ctx, "sink.Write")
+	msg, err := sink.Write(ctx, key, data)
+	writeSpan.End()
+	blobUploadDuration.WithLabelValues(account).Observe(time.Since(sinkStart).Seconds())
 	if err != nil {
-		log.Printf("[%s] Error writing blob '%s': %v", account, blobName, err)
-		return AccountResult{
-			Account: account,
-			Success: false,
-			Error:   fmt.Errorf("failed to write blob: %w", err),
-		}
+		log.Printf("[%s] Error writing to sink (key=%s): %v", account, key, err)
+		span.SetStatus(codes.Error, err.Error())
+		result.Status = "failed"
+		result.Error = fmt.Errorf("failed to write to sink: %w", err).Error()
+		result.DurationMs = time.Since(start).Milliseconds()
+		observeAccountOutcome(account, result.Status, time.Since(start))
+		return result
 	}
+	blobUploadBytes.WithLabelValues(account).Observe(float64(len(data)))
 
-	log.Printf("[%s] Successfully wrote blob: %s", account, blobName)
-	return AccountResult{
-		Account: account,
-		Success: true,
-		Message: result,
-	}
+	log.Printf("[%s] Successfully wrote: %s", account, key)
+	result.Status = "success"
+	result.Message = msg
+	result.DurationMs = time.Since(start).Milliseconds()
+	observeAccountOutcome(account, result.Status, time.Since(start))
+	return result
 }
 
 // getDatesFromQuery returns startDate and endDate parsed from the URL query.
@@ -220,73 +366,98 @@ func getDatesFromQuery(r *http.Request) (string, string) {
 	return startDate, endDate
 }
 
-// azAuth authenticates using DefaultAzureCredential.
+var (
+	azCredOnce sync.Once
+	azCred     *azidentity.DefaultAzureCredential
+	azCredErr  error
+)
+
+// azAuth returns the package-wide DefaultAzureCredential, constructing it
+// once on first use. DefaultAzureCredential performs its own token caching
+// and refresh, so reusing one instance avoids redundant credential-chain
+// probing (env/managed-identity/CLI/etc.) on every request.
 func azAuth() (*azidentity.DefaultAzureCredential, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Printf("Failed to create DefaultAzureCredential: %v", err)
-		return nil, err
-	}
-	return cred, nil
+	azCredOnce.Do(func() {
+		azCred, azCredErr = azidentity.NewDefaultAzureCredential(nil)
+		if azCredErr != nil {
+			log.Printf("Failed to create DefaultAzureCredential: %v", azCredErr)
+		}
+	})
+	return azCred, azCredErr
 }
 
-// retrieveKvSecret retrieves a secret from Azure Key Vault.
-func retrieveKvSecret(secretName string, cred *azidentity.DefaultAzureCredential) (string, error) {
-	client, err := azsecrets.NewClient(keyVaultURL, cred, nil)
+// retrieveKvSecret retrieves a secret from Azure Key Vault, serving from
+// secretCache when the value was fetched within SECRET_CACHE_TTL.
+func retrieveKvSecret(ctx context.Context, secretName string, cred *azidentity.DefaultAzureCredential) (string, error) {
+	ctx, span := startSpan(ctx, "retrieveKvSecret")
+	defer span.End()
+
+	if value, ok := secretCache.Get(secretName); ok {
+		return value, nil
+	}
+
+	start := time.Now()
+	defer func() { kvLookupDuration.WithLabelValues(secretName).Observe(time.Since(start).Seconds()) }()
+
+	client, err := getKvClient(cred)
 	if err != nil {
-		log.Printf("Failed to create KeyVault client: %v\n", err)
+		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
-	resp, err := client.GetSecret(context.Background(), secretName, "", nil)
+	resp, err := client.GetSecret(ctx, secretName, "", nil)
 	if err != nil {
 		log.Printf("KeyVault get secret failed: %v\n", err)
+		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
+
+	secretCache.Set(secretName, *resp.Value)
 	return *resp.Value, nil
 }
 
 // FetchTransactionData makes a GET request to the FIO API.
-func FetchTransactionData(token, startDate, endDate, format string) ([]byte, error) {
+func FetchTransactionData(ctx context.Context, token, startDate, endDate, format string) ([]byte, error) {
+	ctx, span := startSpan(ctx, "FetchTransactionData")
+	defer span.End()
+
 	fullURL := fmt.Sprintf("https://fioapi.fio.cz/v1/rest/periods/%s/%s/%s/transactions.%s", token, startDate, endDate, format)
 	if debug {
 		log.Printf("Making API call to: %s\n", fullURL)
 	}
 
-	resp, err := httpClient.Get(fullURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
 	defer resp.Body.Close()
+	fioHTTPStatusTotal.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
 
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
 			return nil, err
 		}
-		return nil, fmt.Errorf("API returned non-200 status: %d, Response: %s", resp.StatusCode, string(body))
+		err = fmt.Errorf("API returned non-200 status: %d, Response: %s", resp.StatusCode, string(body))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to read API response: %w", err)
 	}
 	return body, nil
 }
 
-// writeBlob uploads the given data as a blob to the specified container.
-func writeBlob(cred azidentity.DefaultAzureCredential, containerName, blobName string, data []byte) (string, error) {
-	client, err := azblob.NewClient(storageAccountURL, &cred, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create blob client: %w", err)
-	}
-	ctx := context.Background()
-	_, err = client.UploadBuffer(ctx, containerName, blobName, data, &azblob.UploadBufferOptions{})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload blob: %w", err)
-	}
-	return "Blob uploaded successfully", nil
-}
-
 // newHTTPClient returns a reusable HTTP client with a configurable timeout.
 func newHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBoundedPreservesOrder(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	results := runBounded(3, items, func(i int, item int) int {
+		// Vary the delay so completion order differs from input order.
+		time.Sleep(time.Duration(len(items)-item) * time.Millisecond)
+		return item * 2
+	})
+
+	for i, item := range items {
+		if results[i] != item*2 {
+			t.Errorf("results[%d] = %d, want %d", i, results[i], item*2)
+		}
+	}
+}
+
+func TestRunBoundedRespectsConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	items := make([]int, 20)
+
+	var current, max int64
+	runBounded(limit, items, func(i int, _ int) int {
+		n := atomic.AddInt64(&current, 1)
+		for {
+			m := atomic.LoadInt64(&max)
+			if n <= m || atomic.CompareAndSwapInt64(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt64(&current, -1)
+		return i
+	})
+
+	if max > limit {
+		t.Errorf("observed %d concurrent workers, want at most %d", max, limit)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fio_pull_requests_total",
+		Help: "Total number of requests received by the puller.",
+	})
+
+	accountFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fio_pull_account_fetch_duration_seconds",
+		Help:    "Duration of the full fetch+store pipeline for a single account.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"account", "status"})
+
+	fioHTTPStatusTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fio_pull_fio_http_status_total",
+		Help: "Count of HTTP status codes returned by the Fio API.",
+	}, []string{"status_code"})
+
+	blobUploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fio_pull_sink_write_duration_seconds",
+		Help:    "Duration of writes to the configured Sink.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"account"})
+
+	blobUploadBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fio_pull_sink_write_bytes",
+		Help:    "Size in bytes of data written to the configured Sink.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"account"})
+
+	kvLookupDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fio_pull_kv_lookup_duration_seconds",
+		Help:    "Duration of Key Vault secret lookups.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"secret"})
+
+	accountOutcomeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fio_pull_account_outcome_total",
+		Help: "Count of account processing outcomes, labeled by account and success/failure.",
+	}, []string{"account", "outcome"})
+)
+
+// metricsHandler serves the Prometheus exposition format at /metrics.
+var metricsHandler http.Handler = promhttp.Handler()
+
+// observeAccountOutcome records the terminal duration/outcome metrics for a
+// single account's processing.
+func observeAccountOutcome(account, status string, duration time.Duration) {
+	accountFetchDuration.WithLabelValues(account, status).Observe(duration.Seconds())
+	accountOutcomeTotal.WithLabelValues(account, status).Inc()
+}
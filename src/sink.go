@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSinkKeyNotFound is returned by Sink.Read when key does not exist, so
+// callers (e.g. loading the persisted account registry on first run) can
+// distinguish "not found" from a transport error.
+var ErrSinkKeyNotFound = errors.New("sink: key not found")
+
+// Sink abstracts the destination that processed transaction data is written
+// to, so the puller isn't locked to Azure Blob Storage.
+type Sink interface {
+	// Write stores data under key, returning a human-readable result message
+	// on success (e.g. for inclusion in AccountResult.Message).
+	Write(ctx context.Context, key string, data []byte) (string, error)
+
+	// Read retrieves the data stored under key, returning ErrSinkKeyNotFound
+	// if it does not exist.
+	Read(ctx context.Context, key string) ([]byte, error)
+}
+
+// sinkKind identifies which Sink implementation to construct.
+type sinkKind string
+
+const (
+	sinkAzure sinkKind = "azure"
+	sinkS3    sinkKind = "s3"
+	sinkGCS   sinkKind = "gcs"
+	sinkFile  sinkKind = "file"
+)
+
+// newSink builds the Sink selected by the SINK env var (default "azure").
+func newSink(ctx context.Context) (Sink, error) {
+	switch kind := sinkKind(getEnvOrDefault("SINK", string(sinkAzure))); kind {
+	case sinkAzure:
+		return newAzureBlobSink()
+	case sinkS3:
+		return newS3Sink(ctx)
+	case sinkGCS:
+		return newGCSSink(ctx)
+	case sinkFile:
+		return newFileSink()
+	default:
+		return nil, fmt.Errorf("unknown SINK %q, expected one of azure|s3|gcs|file", kind)
+	}
+}
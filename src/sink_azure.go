@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// azureBlobSink writes data to Azure Blob Storage. It is the original and
+// still the default Sink implementation.
+type azureBlobSink struct {
+	client        *azblob.Client
+	containerName string
+}
+
+// newAzureBlobSink builds an azureBlobSink using DefaultAzureCredential and
+// the STORAGE_ACCOUNT_URL / STORAGE_CONTAINER_NAME env vars.
+func newAzureBlobSink() (*azureBlobSink, error) {
+	cred, err := azAuth()
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate for blob sink: %w", err)
+	}
+	client, err := azblob.NewClient(storageAccountURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+	return &azureBlobSink{client: client, containerName: storageContainerName}, nil
+}
+
+// Write uploads data as a blob named key in the configured container.
+func (s *azureBlobSink) Write(ctx context.Context, key string, data []byte) (string, error) {
+	_, err := s.client.UploadBuffer(ctx, s.containerName, key, data, &azblob.UploadBufferOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload blob: %w", err)
+	}
+	return "Blob uploaded successfully", nil
+}
+
+// Read downloads the blob named key from the configured container.
+func (s *azureBlobSink) Read(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.DownloadStream(ctx, s.containerName, key, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil, ErrSinkKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to download blob: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read blob body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var fileSinkDir = getEnvOrDefault("FILE_SINK_DIR", "./data")
+
+// fileSink writes data to the local filesystem. It exists mainly for dev and
+// integration testing, where spinning up an Azure/S3/GCS emulator is overkill.
+type fileSink struct {
+	baseDir string
+}
+
+// newFileSink builds a fileSink rooted at FILE_SINK_DIR.
+func newFileSink() (*fileSink, error) {
+	if err := os.MkdirAll(fileSinkDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create file sink dir %q: %w", fileSinkDir, err)
+	}
+	return &fileSink{baseDir: fileSinkDir}, nil
+}
+
+// Write stores data at baseDir/key, creating any intermediate directories.
+func (s *fileSink) Write(ctx context.Context, key string, data []byte) (string, error) {
+	path := filepath.Join(s.baseDir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write file %q: %w", path, err)
+	}
+	return fmt.Sprintf("File written successfully: %s", path), nil
+}
+
+// Read reads data from baseDir/key.
+func (s *fileSink) Read(ctx context.Context, key string) ([]byte, error) {
+	path := filepath.Join(s.baseDir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSinkKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to read file %q: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+var gcsBucket = getEnvOrDefault("GCS_BUCKET", "")
+
+// gcsSink writes data to a Google Cloud Storage bucket.
+type gcsSink struct {
+	client *storage.Client
+	bucket string
+}
+
+// newGCSSink builds a gcsSink using application default credentials and the
+// GCS_BUCKET env var.
+func newGCSSink(ctx context.Context) (*gcsSink, error) {
+	if gcsBucket == "" {
+		return nil, fmt.Errorf("GCS_BUCKET must be set when SINK=gcs")
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &gcsSink{client: client, bucket: gcsBucket}, nil
+}
+
+// Write uploads data as an object named key in the configured bucket.
+func (s *gcsSink) Write(ctx context.Context, key string, data []byte) (string, error) {
+	w := s.client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("failed to write GCS object: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize GCS object: %w", err)
+	}
+	return "Object uploaded successfully", nil
+}
+
+// Read downloads the object named key from the configured bucket.
+func (s *gcsSink) Read(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrSinkKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to open GCS object: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCS object: %w", err)
+	}
+	return data, nil
+}
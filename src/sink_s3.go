@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	s3Bucket = getEnvOrDefault("S3_BUCKET", "")
+	s3Region = getEnvOrDefault("S3_REGION", "eu-central-1")
+)
+
+// s3Sink writes data to an AWS S3 bucket.
+type s3Sink struct {
+	client *s3.Client
+	bucket string
+}
+
+// newS3Sink builds an s3Sink from the default AWS config chain (env vars,
+// shared config/credentials files, or an attached IAM role) and the
+// S3_BUCKET / S3_REGION env vars.
+func newS3Sink(ctx context.Context) (*s3Sink, error) {
+	if s3Bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET must be set when SINK=s3")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(s3Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &s3Sink{client: s3.NewFromConfig(cfg), bucket: s3Bucket}, nil
+}
+
+// Write uploads data as an object named key in the configured bucket.
+func (s *s3Sink) Write(ctx context.Context, key string, data []byte) (string, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to put S3 object: %w", err)
+	}
+	return "Object uploaded successfully", nil
+}
+
+// Read downloads the object named key from the configured bucket.
+func (s *s3Sink) Read(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrSinkKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get S3 object: %w", err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 object body: %w", err)
+	}
+	return data, nil
+}
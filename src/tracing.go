@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// serviceName identifies this service in exported traces.
+const serviceName = "go-fio-pull"
+
+// tracer is the package-wide tracer used to create spans across the
+// fetch/store pipeline.
+var tracer = otel.Tracer(serviceName)
+
+// initTracer wires up an OTLP/gRPC span exporter configured via the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT (and related) env vars, and registers it as the
+// global TracerProvider. It returns a shutdown func to flush on exit.
+func initTracer(ctx context.Context) (func(context.Context) error, error) {
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OTel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// startSpan is a small convenience wrapper so call sites don't repeat the
+// tracer.Start boilerplate.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// shutdownTracer is called from main on exit; errors are logged, not fatal,
+// since a flaky collector shouldn't take down the service.
+func shutdownTracer(ctx context.Context, shutdown func(context.Context) error) {
+	if shutdown == nil {
+		return
+	}
+	if err := shutdown(ctx); err != nil {
+		log.Printf("Failed to shut down tracer provider: %v", err)
+	}
+}